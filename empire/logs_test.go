@@ -0,0 +1,153 @@
+package empire
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type fakeLogsStreamer struct {
+	lines  []string
+	tailFn func(ctx context.Context, app *App, w io.Writer) error
+}
+
+func (f *fakeLogsStreamer) StreamLogs(ctx context.Context, app *App, w io.Writer, tail bool) error {
+	if !tail {
+		for _, l := range f.lines {
+			if _, err := io.WriteString(w, l); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return f.tailFn(ctx, app, w)
+}
+
+func TestLogFilter_Matches(t *testing.T) {
+	tests := []struct {
+		filter LogFilter
+		line   string
+		want   bool
+	}{
+		{LogFilter{}, "anything", true},
+		{LogFilter{ProcessType: "web"}, "source=app ps=web.1 hi", true},
+		{LogFilter{ProcessType: "web"}, "source=app ps=worker.1 hi", false},
+		{LogFilter{InstanceID: "web.2"}, "source=app ps=web.1 hi", false},
+		{LogFilter{InstanceID: "web.2"}, "source=app ps=web.2 hi", true},
+		{LogFilter{Source: "heroku"}, "source=app ps=web.1 hi", false},
+		{LogFilter{Source: "app"}, "source=app ps=web.1 hi", true},
+		{LogFilter{ProcessType: "web"}, "an untagged line", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Matches(tt.line); got != tt.want {
+			t.Fatalf("Matches(%q) with %#v => %t; want %t", tt.line, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestFilteredWriter(t *testing.T) {
+	var buf bytes.Buffer
+	fw := &filteredWriter{w: &buf, filter: LogFilter{ProcessType: "web"}}
+
+	// A line split across two Writes should still be matched as a whole.
+	if _, err := fw.Write([]byte("source=app ps=web.1 hel")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte("lo\nsource=app ps=worker.1 bye\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := buf.String(), "source=app ps=web.1 hello\n"; got != want {
+		t.Fatalf("buf => %q; want %q", got, want)
+	}
+}
+
+func TestTailWriter(t *testing.T) {
+	var buf bytes.Buffer
+	tw := &tailWriter{w: &buf, lines: 2}
+
+	if _, err := tw.Write([]byte("line1\nline2\nline3\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("line4")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := buf.String(), "line3\nline4"; got != want {
+		t.Fatalf("buf => %q; want %q", got, want)
+	}
+}
+
+func TestTailWriter_linesZero(t *testing.T) {
+	var buf bytes.Buffer
+	tw := &tailWriter{w: &buf, lines: 0}
+
+	if _, err := tw.Write([]byte("line1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("buf => %q; want empty", buf.String())
+	}
+}
+
+func TestEmpire_StreamLogs_noTail(t *testing.T) {
+	streamer := &fakeLogsStreamer{lines: []string{
+		"source=app ps=web.1 line1\n",
+		"source=app ps=web.1 line2\n",
+		"source=app ps=web.2 line3\n",
+	}}
+	e := &Empire{LogsStreamer: streamer}
+
+	var buf bytes.Buffer
+	err := e.StreamLogs(context.Background(), &App{}, &buf, false, 1, LogFilter{InstanceID: "web.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := buf.String(), "source=app ps=web.1 line2\n"; got != want {
+		t.Fatalf("buf => %q; want %q", got, want)
+	}
+}
+
+func TestEmpire_StreamLogs_tailCancel(t *testing.T) {
+	started := make(chan struct{})
+	streamer := &fakeLogsStreamer{
+		tailFn: func(ctx context.Context, app *App, w io.Writer) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	e := &Empire{LogsStreamer: streamer}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.StreamLogs(ctx, &App{}, &buf, true, 0, LogFilter{})
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if err != context.Canceled {
+			t.Fatalf("err => %v; want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("StreamLogs did not return after the context was canceled")
+	}
+}
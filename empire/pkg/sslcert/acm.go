@@ -0,0 +1,77 @@
+package sslcert
+
+import (
+	"github.com/awslabs/aws-sdk-go/aws"
+	"github.com/awslabs/aws-sdk-go/service/acm"
+)
+
+// acmClient is the subset of the ACM API that ACMManager depends on. It's
+// defined as an interface so it can be faked in tests.
+type acmClient interface {
+	ImportCertificate(*acm.ImportCertificateInput) (*acm.ImportCertificateOutput, error)
+	DeleteCertificate(*acm.DeleteCertificateInput) (*acm.DeleteCertificateOutput, error)
+	DescribeCertificate(*acm.DescribeCertificateInput) (*acm.DescribeCertificateOutput, error)
+}
+
+// ACMManager is a Manager implementation that imports certificates into AWS
+// Certificate Manager. ACM certificates are referenced by ARN, which is what
+// ELBv2/ALB listeners expect, as opposed to the legacy IAM server
+// certificates that classic ELBs use.
+type ACMManager struct {
+	acm acmClient
+}
+
+// NewACMManager returns a new ACMManager backed by the given aws.Config.
+func NewACMManager(config *aws.Config) *ACMManager {
+	return &ACMManager{
+		acm: acm.New(config),
+	}
+}
+
+// Add imports a certificate into ACM and returns its ARN. Unlike IAM, ACM has
+// no concept of a friendly certificate name, so the name argument is only
+// used to request an update of an existing certificate when it already holds
+// an ARN.
+func (m *ACMManager) Add(name string, cert string, key string) (string, error) {
+	primary, chain := SplitCertChain(cert)
+	input := &acm.ImportCertificateInput{
+		Certificate: []byte(primary),
+		PrivateKey:  []byte(key),
+	}
+
+	if len(chain) > 0 {
+		input.CertificateChain = []byte(chain)
+	}
+
+	if IsACMARN(name) {
+		input.CertificateArn = aws.String(name)
+	}
+
+	output, err := m.acm.ImportCertificate(input)
+	if err != nil {
+		return "", err
+	}
+
+	return *output.CertificateArn, nil
+}
+
+// Remove deletes the certificate with the given ARN from ACM.
+func (m *ACMManager) Remove(name string) error {
+	_, err := m.acm.DeleteCertificate(&acm.DeleteCertificateInput{CertificateArn: aws.String(name)})
+	return err
+}
+
+// MetaData returns metadata about the certificate with the given ARN.
+func (m *ACMManager) MetaData(name string) (map[string]string, error) {
+	data := map[string]string{}
+	out, err := m.acm.DescribeCertificate(&acm.DescribeCertificateInput{CertificateArn: aws.String(name)})
+	if err != nil {
+		return data, err
+	}
+
+	if out.Certificate.CertificateArn != nil {
+		data["ARN"] = *out.Certificate.CertificateArn
+	}
+
+	return data, nil
+}
@@ -0,0 +1,169 @@
+package sslcert
+
+import (
+	"testing"
+
+	"github.com/awslabs/aws-sdk-go/aws"
+	"github.com/awslabs/aws-sdk-go/service/acm"
+)
+
+type mockACMClient struct {
+	importCertificate   func(*acm.ImportCertificateInput) (*acm.ImportCertificateOutput, error)
+	deleteCertificate   func(*acm.DeleteCertificateInput) (*acm.DeleteCertificateOutput, error)
+	describeCertificate func(*acm.DescribeCertificateInput) (*acm.DescribeCertificateOutput, error)
+}
+
+func (m *mockACMClient) ImportCertificate(input *acm.ImportCertificateInput) (*acm.ImportCertificateOutput, error) {
+	return m.importCertificate(input)
+}
+
+func (m *mockACMClient) DeleteCertificate(input *acm.DeleteCertificateInput) (*acm.DeleteCertificateOutput, error) {
+	return m.deleteCertificate(input)
+}
+
+func (m *mockACMClient) DescribeCertificate(input *acm.DescribeCertificateInput) (*acm.DescribeCertificateOutput, error) {
+	return m.describeCertificate(input)
+}
+
+func TestACMManager_Add(t *testing.T) {
+	client := &mockACMClient{
+		importCertificate: func(input *acm.ImportCertificateInput) (*acm.ImportCertificateOutput, error) {
+			return &acm.ImportCertificateOutput{
+				CertificateArn: aws.String("arn:aws:acm:us-east-1:012345678901:certificate/abcd"),
+			}, nil
+		},
+	}
+	m := &ACMManager{acm: client}
+
+	arn, err := m.Add("web", "cert", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := arn, "arn:aws:acm:us-east-1:012345678901:certificate/abcd"; got != want {
+		t.Fatalf("arn => %s; want %s", got, want)
+	}
+}
+
+func TestACMManager_Remove(t *testing.T) {
+	var removed string
+	client := &mockACMClient{
+		deleteCertificate: func(input *acm.DeleteCertificateInput) (*acm.DeleteCertificateOutput, error) {
+			removed = *input.CertificateArn
+			return &acm.DeleteCertificateOutput{}, nil
+		},
+	}
+	m := &ACMManager{acm: client}
+
+	arn := "arn:aws:acm:us-east-1:012345678901:certificate/abcd"
+	if err := m.Remove(arn); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != arn {
+		t.Fatalf("removed => %s; want %s", removed, arn)
+	}
+}
+
+func TestACMManager_MetaData(t *testing.T) {
+	arn := "arn:aws:acm:us-east-1:012345678901:certificate/abcd"
+	client := &mockACMClient{
+		describeCertificate: func(input *acm.DescribeCertificateInput) (*acm.DescribeCertificateOutput, error) {
+			return &acm.DescribeCertificateOutput{
+				Certificate: &acm.CertificateDetail{
+					CertificateArn: aws.String(arn),
+				},
+			}, nil
+		},
+	}
+	m := &ACMManager{acm: client}
+
+	data, err := m.MetaData(arn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := data["ARN"], arn; got != want {
+		t.Fatalf("ARN => %s; want %s", got, want)
+	}
+}
+
+func TestNewManager(t *testing.T) {
+	awsConfig := &aws.Config{}
+
+	m, err := NewManager(Config{Backend: ACM}, awsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(*ACMManager); !ok {
+		t.Fatalf("expected *ACMManager, got %T", m)
+	}
+
+	m, err = NewManager(Config{Backend: IAM, Path: "/"}, awsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(*IAMManager); !ok {
+		t.Fatalf("expected *IAMManager, got %T", m)
+	}
+
+	if _, err := NewManager(Config{Backend: "bogus"}, awsConfig); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
+
+func TestIsACMARN(t *testing.T) {
+	tests := []struct {
+		cert string
+		want bool
+	}{
+		{"arn:aws:acm:us-east-1:012345678901:certificate/abcd", true},
+		{"my-server-cert", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsACMARN(tt.cert); got != tt.want {
+			t.Fatalf("IsACMARN(%q) => %t; want %t", tt.cert, got, tt.want)
+		}
+	}
+}
+
+func TestManagerFor(t *testing.T) {
+	iam := &IAMManager{}
+	acm := &ACMManager{}
+
+	if got := ManagerFor("arn:aws:acm:us-east-1:012345678901:certificate/abcd", iam, acm); got != Manager(acm) {
+		t.Fatalf("ManagerFor(arn) => %v; want acm", got)
+	}
+
+	if got := ManagerFor("my-server-cert", iam, acm); got != Manager(iam) {
+		t.Fatalf("ManagerFor(name) => %v; want iam", got)
+	}
+}
+
+func TestSplitCertChain(t *testing.T) {
+	leaf := "-----BEGIN CERTIFICATE-----\nleaf\n-----END CERTIFICATE-----"
+	intermediate := "-----BEGIN CERTIFICATE-----\nintermediate\n-----END CERTIFICATE-----"
+
+	t.Run("leaf only", func(t *testing.T) {
+		primary, chain := SplitCertChain(leaf)
+		if primary != leaf {
+			t.Fatalf("primary => %q; want %q", primary, leaf)
+		}
+		if chain != "" {
+			t.Fatalf("chain => %q; want empty", chain)
+		}
+	})
+
+	t.Run("leaf and chain", func(t *testing.T) {
+		bundle := leaf + "\n" + intermediate
+		primary, chain := SplitCertChain(bundle)
+		if primary != leaf {
+			t.Fatalf("primary => %q; want %q", primary, leaf)
+		}
+		if chain != intermediate {
+			t.Fatalf("chain => %q; want %q", chain, intermediate)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+// Package sslcert provides an abstraction for managing SSL certificates used
+// by HTTPSExposure processes.
+package sslcert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awslabs/aws-sdk-go/aws"
+)
+
+// Manager represents something that can manage SSL certificates on behalf of
+// Empire. Implementations upload or import a certificate and return an
+// identifier for it: IAMManager returns the IAM server certificate ID, and
+// ACMManager returns the ACM certificate ARN.
+type Manager interface {
+	// Add adds a new certificate, returning an identifier that can be
+	// used to reference it later.
+	Add(name string, cert string, key string) (string, error)
+
+	// Remove removes a certificate.
+	Remove(name string) error
+
+	// MetaData returns metadata about a certificate.
+	MetaData(name string) (map[string]string, error)
+}
+
+// Backend is the type of certificate backend to use.
+type Backend string
+
+const (
+	// IAM uploads certificates to AWS IAM as server certificates.
+	IAM Backend = "iam"
+
+	// ACM imports certificates into AWS Certificate Manager.
+	ACM Backend = "acm"
+)
+
+// Config is used to configure which Manager implementation NewManager
+// returns.
+type Config struct {
+	// Backend selects the Manager implementation to use. Defaults to IAM.
+	Backend Backend
+
+	// Path is the IAM path to prefix server certificates with. Only used
+	// when Backend is IAM.
+	Path string
+}
+
+// NewManager returns a new Manager for the given config.
+func NewManager(config Config, awsConfig *aws.Config) (Manager, error) {
+	switch config.Backend {
+	case "", IAM:
+		return NewIAMManager(awsConfig, config.Path), nil
+	case ACM:
+		return NewACMManager(awsConfig), nil
+	default:
+		return nil, fmt.Errorf("sslcert: unknown backend %q", config.Backend)
+	}
+}
+
+// IsACMARN reports whether cert looks like an ACM certificate ARN, as
+// opposed to the name of an IAM server certificate.
+func IsACMARN(cert string) bool {
+	return strings.HasPrefix(cert, "arn:aws:acm:")
+}
+
+// ManagerFor returns the Manager that's able to resolve cert: acm if cert is
+// an ACM ARN, otherwise iam. This is what a scheduler backend should use to
+// decide which manager to consult for a HTTPSExposure.Cert, since that field
+// can hold either an IAM certificate name or an ACM ARN.
+func ManagerFor(cert string, iam, acm Manager) Manager {
+	if IsACMARN(cert) {
+		return acm
+	}
+	return iam
+}
+
+// SplitCertChain splits a PEM encoded cert bundle into its leaf certificate
+// and the remaining intermediate chain, if any. Callers (e.g. Manager.Add
+// implementations) upload the two separately, since IAM and ACM both expect
+// the chain as a distinct value from the leaf certificate.
+func SplitCertChain(cert string) (primary string, chain string) {
+	primary = cert
+	chain = ""
+
+	i := strings.Index(cert, "-----END CERTIFICATE-----")
+	if i == -1 {
+		return primary, chain
+	}
+	i += len("-----END CERTIFICATE-----")
+
+	primary = strings.TrimSpace(cert[:i])
+	chain = strings.TrimSpace(cert[i:])
+	return primary, chain
+}
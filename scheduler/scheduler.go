@@ -4,7 +4,9 @@ package scheduler
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
@@ -51,7 +53,16 @@ type Process struct {
 	// Labels to set on the container.
 	Labels map[string]string
 
+	// PortMappings are the ports that this process exposes. A process can
+	// expose more than one port (e.g. an HTTP port alongside a gRPC or
+	// admin port), each with its own Exposure.
+	PortMappings []PortMapping
+
 	// Exposure is the level of exposure for this process.
+	//
+	// Deprecated: Use PortMappings instead. This field is kept for one
+	// release as a shim; Ports returns it as a single PortMapping when
+	// PortMappings is empty.
 	Exposure *Exposure
 
 	// Instances is the desired instances of this service to run.
@@ -66,6 +77,143 @@ type Process struct {
 
 	// ulimit -u
 	Nproc uint
+
+	// Constraints restricts where instances of this process may be
+	// scheduled. A nil Constraints means the process can be scheduled
+	// anywhere the cluster allows.
+	Constraints *Constraints
+}
+
+// Constraints captures placement constraints for where a process's
+// instances may be scheduled.
+type Constraints struct {
+	// Platform is the CPU architecture and OS that this process must run
+	// on (e.g. "linux/amd64", "linux/arm64"). It's used to select the
+	// task definition's CPU architecture, and is passed through to the
+	// docker/attached backend on image pull and container create.
+	Platform string
+
+	// AttributeExpressions are ECS instance attribute expressions that a
+	// container instance must satisfy to be eligible to run this
+	// process (e.g. "attribute:ecs.instance-type =~ m5.*").
+	AttributeExpressions []string
+
+	// DistinctInstance requires that no two instances of this process
+	// run on the same container instance, e.g. to spread a process
+	// across availability zones.
+	DistinctInstance bool
+}
+
+// PlacementConstraintExpressions returns the placement constraint
+// expressions implied by these Constraints: the configured
+// AttributeExpressions, plus a "distinctInstance" marker when
+// DistinctInstance is set. Backends (e.g. the ECS service's
+// placementConstraints) translate these into their own representation. A
+// nil Constraints returns no expressions.
+//
+// TODO: no backend in this tree calls this yet. The ECS service/task
+// rendering that needs it doesn't exist here.
+func (c *Constraints) PlacementConstraintExpressions() []string {
+	if c == nil {
+		return nil
+	}
+
+	exprs := make([]string, len(c.AttributeExpressions), len(c.AttributeExpressions)+1)
+	copy(exprs, c.AttributeExpressions)
+
+	if c.DistinctInstance {
+		exprs = append(exprs, "distinctInstance")
+	}
+
+	return exprs
+}
+
+// Platform returns the process's Constraints.Platform, or "" if the process
+// has no Constraints. Backends use this to select a task definition's CPU
+// architecture, and to pass the right platform on image pull/container
+// create for the docker/attached backend.
+//
+// TODO: no backend in this tree calls this yet. The ECS task-definition
+// rendering and the docker/attached image pull that need it don't exist
+// here.
+func (p *Process) Platform() string {
+	if p.Constraints == nil {
+		return ""
+	}
+	return p.Constraints.Platform
+}
+
+// defaultContainerPort is the port that a process is assumed to listen on
+// when it defines an Exposure but no explicit PortMappings, matching the
+// convention of the PORT environment variable.
+const defaultContainerPort = 8080
+
+// Ports returns the PortMappings for this process. If PortMappings is empty,
+// it falls back to synthesizing a single PortMapping from the deprecated
+// Exposure field, so that backends only ever need to deal with
+// PortMappings.
+func (p *Process) Ports() []PortMapping {
+	if len(p.PortMappings) > 0 {
+		return p.PortMappings
+	}
+
+	if p.Exposure == nil {
+		return nil
+	}
+
+	return []PortMapping{
+		{
+			ContainerPort: defaultContainerPort,
+			Protocol:      "tcp",
+			Exposure:      p.Exposure,
+		},
+	}
+}
+
+// PortMapping represents a single port that a process exposes, and how it
+// should be exposed.
+type PortMapping struct {
+	// ContainerPort is the port that the process listens on inside the
+	// container.
+	ContainerPort uint
+
+	// HostPort is the port on the host that ContainerPort is bound to.
+	// If zero, a port is dynamically allocated by the backend.
+	HostPort uint
+
+	// Protocol is the transport protocol for this port (e.g. "tcp" or
+	// "udp"). Defaults to "tcp".
+	Protocol string
+
+	// Exposure controls how this port is exposed, if at all. A nil
+	// Exposure means the port is only reachable from within the
+	// container's host.
+	Exposure *Exposure
+}
+
+// portEnv returns the PORT and PORT_<TYPE>_<CONTAINERPORT> environment
+// variables that should be set for this mapping. <TYPE> is the uppercased
+// protocol of the mapping's Exposure (e.g. PORT_HTTP_8080, PORT_HTTPS_8443,
+// PORT_TCP_9000). The container port is always part of the key: a process
+// can have more than one mapping with the same protocol (e.g. a public HTTP
+// port alongside an internal HTTP admin port), and without it those mappings
+// would collide on the same key and silently overwrite each other. When
+// primary is true, the mapping is also exposed as the bare PORT variable,
+// for compatibility with processes that only expose a single port.
+func (m PortMapping) portEnv(primary bool) map[string]string {
+	env := make(map[string]string)
+
+	port := fmt.Sprintf("%d", m.ContainerPort)
+	if primary {
+		env["PORT"] = port
+	}
+
+	if m.Exposure != nil {
+		key := fmt.Sprintf("PORT_%s_%d", strings.ToUpper(m.Exposure.Type.Protocol()), m.ContainerPort)
+		env[key] = port
+	}
+
+	return env
 }
 
 // Exposure controls the exposure settings for a process.
@@ -87,18 +235,126 @@ type ExposureType interface {
 }
 
 // HTTPExposure represents an HTTP exposure.
-type HTTPExposure struct{}
+type HTTPExposure struct {
+	// HealthCheck controls how instances of this process are health
+	// checked. If nil, a default health check is used.
+	HealthCheck *HealthCheck
+}
 
 func (e *HTTPExposure) Protocol() string { return "http" }
 
 // HTTPSExposure represents an HTTPS exposure
 type HTTPSExposure struct {
-	// The certificate to attach to the process.
+	// The certificate to attach to the process. This can either be the
+	// name of an IAM server certificate, or the ARN of an AWS
+	// Certificate Manager certificate. Backends determine which, and
+	// resolve the right sslcert.Manager to consult, via
+	// sslcert.ManagerFor.
+	//
+	// TODO: no backend in this tree calls sslcert.ManagerFor yet. The
+	// ECS scheduler backend needs to consult it when rendering a
+	// listener's certificate ARN; that backend doesn't exist here.
 	Cert string
+
+	// HealthCheck controls how instances of this process are health
+	// checked. If nil, a default health check is used.
+	HealthCheck *HealthCheck
 }
 
 func (e *HTTPSExposure) Protocol() string { return "https" }
 
+// TCPExposure represents a raw TCP exposure.
+type TCPExposure struct {
+	// HealthCheck controls how instances of this process are health
+	// checked. If nil, a default health check is used.
+	HealthCheck *HealthCheck
+}
+
+func (e *TCPExposure) Protocol() string { return "tcp" }
+
+// HealthCheck controls how a backend determines whether an instance of a
+// process is healthy.
+//
+// For HTTP/HTTPS exposures, Path and Matcher are used to perform an HTTP
+// health check. For TCP exposures, Path and Matcher are ignored and a plain
+// TCP connect is used instead.
+type HealthCheck struct {
+	// Path is the path to request when performing an HTTP health check
+	// (e.g. "/health").
+	Path string
+
+	// Interval is the approximate amount of time between health checks.
+	Interval time.Duration
+
+	// Timeout is the amount of time to wait for a health check to
+	// respond before considering it failed.
+	Timeout time.Duration
+
+	// HealthyThreshold is the number of consecutive successful health
+	// checks required before an instance is considered healthy.
+	HealthyThreshold uint
+
+	// UnhealthyThreshold is the number of consecutive failed health
+	// checks required before an instance is considered unhealthy.
+	UnhealthyThreshold uint
+
+	// Matcher is the range of HTTP status codes that are considered a
+	// successful response (e.g. "200-299"). Only used for HTTP/HTTPS
+	// exposures.
+	Matcher string
+}
+
+// defaultHealthCheck returns the HealthCheck that's applied to an exposure of
+// the given protocol when it doesn't configure one of its own.
+func defaultHealthCheck(protocol string) *HealthCheck {
+	hc := &HealthCheck{
+		Interval:           10 * time.Second,
+		Timeout:            5 * time.Second,
+		HealthyThreshold:   2,
+		UnhealthyThreshold: 2,
+	}
+
+	if protocol == "http" || protocol == "https" {
+		hc.Path = "/"
+		hc.Matcher = "200-299"
+	}
+
+	return hc
+}
+
+// healthCheck returns the HealthCheck configured on the underlying exposure
+// type, if any.
+func healthCheck(t ExposureType) *HealthCheck {
+	switch e := t.(type) {
+	case *HTTPExposure:
+		return e.HealthCheck
+	case *HTTPSExposure:
+		return e.HealthCheck
+	case *TCPExposure:
+		return e.HealthCheck
+	default:
+		return nil
+	}
+}
+
+// HealthCheck returns the HealthCheck that a backend should use for
+// instances of this exposure: the HealthCheck configured on its
+// HTTPExposure/HTTPSExposure/TCPExposure, or a protocol-appropriate default
+// when none is configured. Backends should call this rather than reading
+// the per-type field directly, so that every backend (ECS target groups,
+// the docker/attached prober, ...) applies the same defaulting behavior.
+//
+// TODO: no backend in this tree calls this yet. The ECS scheduler backend
+// needs to translate it into a target group's HealthCheck* properties, and
+// the docker/attached backends need to perform periodic probes against it;
+// neither exists here.
+func (e *Exposure) HealthCheck() *HealthCheck {
+	if hc := healthCheck(e.Type); hc != nil {
+		return hc
+	}
+	return defaultHealthCheck(e.Type.Protocol())
+}
+
 // Instance represents an Instance of a Process.
 type Instance struct {
 	Process *Process
@@ -106,7 +362,9 @@ type Instance struct {
 	// The instance ID.
 	ID string
 
-	// The State that this Instance is in.
+	// The State that this Instance is in (e.g. "running", "pending",
+	// "unhealthy"). For exposed processes, this reflects the result of
+	// the configured HealthCheck, if any.
 	State string
 
 	// The time that this instance was last updated.
@@ -114,7 +372,12 @@ type Instance struct {
 }
 
 type Runner interface {
-	// Run runs a process.
+	// Run runs a process. The process's PORT and
+	// PORT_<TYPE>_<CONTAINERPORT> environment variables, as determined by
+	// its PortMappings, are merged into the
+	// environment that the process is run with. If the process's
+	// Constraints specify a Platform, it's used when pulling the image
+	// and creating the container.
 	Run(ctx context.Context, app *App, process *Process, in io.Reader, out io.Writer) error
 }
 
@@ -137,9 +400,21 @@ type Scheduler interface {
 }
 
 // Env merges the App environment with any environment variables provided
-// in the process.
+// in the process, as well as the PORT and PORT_<TYPE>_<CONTAINERPORT>
+// variables derived from the process's PortMappings.
 func Env(app *App, process *Process) map[string]string {
-	return merge(app.Env, process.Env)
+	return merge(app.Env, process.Env, portsEnv(process))
+}
+
+// portsEnv returns the merged PORT and PORT_<TYPE>_<CONTAINERPORT> environment
+// variables for all of a process's PortMappings. The first mapping is exposed as the bare
+// PORT variable.
+func portsEnv(process *Process) map[string]string {
+	envs := make([]map[string]string, 0, len(process.Ports()))
+	for i, mapping := range process.Ports() {
+		envs = append(envs, mapping.portEnv(i == 0))
+	}
+	return merge(envs...)
 }
 
 // Labels merges the App labels with any labels provided in the process.
@@ -159,13 +434,68 @@ func merge(envs ...map[string]string) map[string]string {
 }
 
 type Status struct {
+	// ID identifies the phase or resource that this status update is
+	// about (e.g. a CloudFormation logical resource ID). Optional.
+	ID string
+
 	// A friendly human readable message about the status change.
 	Message string
+
+	// Progress describes how far along a long running operation is.
+	// Optional.
+	Progress *Progress
+
+	// Error is set when this status update represents a failure.
+	Error error
+}
+
+// Progress describes how far along a long running operation is, e.g. the
+// number of CloudFormation stack events that have been applied so far while
+// updating a service.
+type Progress struct {
+	// Current is the number of units completed so far.
+	Current int
+
+	// Total is the total number of units expected to complete.
+	Total int
+
+	// Units labels what Current and Total are counting (e.g. "events").
+	Units string
+}
+
+// ResourceStatus returns a Status for a single resource's progress during a
+// multi-resource operation, such as a CloudFormation stack update applying
+// one resource at a time. id is typically the resource's logical ID (e.g.
+// "web"), and message its current state (e.g. "CREATE_IN_PROGRESS"). A
+// scheduler backend that drives a multi-step update should publish one of
+// these per resource per state transition, rather than a single flat
+// Status, so that callers like emp deploy can render per-resource progress
+// instead of a linear log tail.
+//
+// TODO: no backend in this tree calls this yet. The ECS scheduler backend
+// needs to publish one of these per CloudFormation stack event as a
+// deployment progresses; that backend doesn't exist here.
+func ResourceStatus(id, message string, current, total int) Status {
+	return Status{
+		ID:      id,
+		Message: message,
+		Progress: &Progress{
+			Current: current,
+			Total:   total,
+			Units:   "events",
+		},
+	}
 }
 
 // String implements the fmt.Stringer interface.
 func (s *Status) String() string {
-	return s.Message
+	if s.Message != "" {
+		return s.Message
+	}
+	if s.Error != nil {
+		return s.Error.Error()
+	}
+	return ""
 }
 
 // StatusStream is an interface for publishing status updates while a scheduler
@@ -206,7 +536,26 @@ func (s *jsonmessageStatusStream) Publish(ctx context.Context, status Status) er
 		return nil
 	default:
 	}
-	return json.NewEncoder(s.w).Encode(jsonmessage.JSONMessage{Status: status.Message})
+
+	msg := jsonmessage.JSONMessage{
+		ID:     status.ID,
+		Status: status.Message,
+	}
+
+	if status.Progress != nil {
+		msg.Progress = &jsonmessage.JSONProgress{
+			Current: int64(status.Progress.Current),
+			Total:   int64(status.Progress.Total),
+		}
+		msg.ProgressMessage = fmt.Sprintf("%d/%d %s", status.Progress.Current, status.Progress.Total, status.Progress.Units)
+	}
+
+	if status.Error != nil {
+		msg.Error = &jsonmessage.JSONError{Message: status.Error.Error()}
+		msg.ErrorMessage = status.Error.Error()
+	}
+
+	return json.NewEncoder(s.w).Encode(msg)
 }
 
 func (s *jsonmessageStatusStream) Done(err error) {
@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"golang.org/x/net/context"
+)
+
+func TestProcess_Ports(t *testing.T) {
+	t.Run("PortMappings set", func(t *testing.T) {
+		mappings := []PortMapping{
+			{ContainerPort: 8080, Protocol: "tcp"},
+			{ContainerPort: 9090, Protocol: "tcp"},
+		}
+		p := &Process{PortMappings: mappings, Exposure: &Exposure{Type: &HTTPExposure{}}}
+
+		if got := p.Ports(); !reflect.DeepEqual(got, mappings) {
+			t.Fatalf("Ports() => %#v; want %#v", got, mappings)
+		}
+	})
+
+	t.Run("falls back to Exposure", func(t *testing.T) {
+		exposure := &Exposure{Type: &HTTPExposure{}}
+		p := &Process{Exposure: exposure}
+
+		got := p.Ports()
+		want := []PortMapping{
+			{ContainerPort: defaultContainerPort, Protocol: "tcp", Exposure: exposure},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Ports() => %#v; want %#v", got, want)
+		}
+	})
+
+	t.Run("no ports", func(t *testing.T) {
+		p := &Process{}
+		if got := p.Ports(); got != nil {
+			t.Fatalf("Ports() => %#v; want nil", got)
+		}
+	})
+}
+
+func TestEnv_PortsWithSameProtocol(t *testing.T) {
+	app := &App{}
+	process := &Process{
+		PortMappings: []PortMapping{
+			{ContainerPort: 8080, Protocol: "tcp", Exposure: &Exposure{Type: &HTTPExposure{}}},
+			{ContainerPort: 8081, Protocol: "tcp", Exposure: &Exposure{Type: &HTTPExposure{}}},
+		},
+	}
+
+	env := Env(app, process)
+
+	want := map[string]string{
+		"PORT":           "8080",
+		"PORT_HTTP_8080": "8080",
+		"PORT_HTTP_8081": "8081",
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Fatalf("env[%q] => %q; want %q (env: %#v)", k, env[k], v, env)
+		}
+	}
+}
+
+func TestExposure_HealthCheck(t *testing.T) {
+	t.Run("configured", func(t *testing.T) {
+		hc := &HealthCheck{Path: "/healthz"}
+		e := &Exposure{Type: &HTTPExposure{HealthCheck: hc}}
+
+		if got := e.HealthCheck(); got != hc {
+			t.Fatalf("HealthCheck() => %v; want %v", got, hc)
+		}
+	})
+
+	t.Run("defaults for http", func(t *testing.T) {
+		e := &Exposure{Type: &HTTPExposure{}}
+
+		got := e.HealthCheck()
+		if got.Path != "/" || got.Matcher != "200-299" {
+			t.Fatalf("HealthCheck() => %#v; want a default HTTP health check", got)
+		}
+		if got.Interval != 10*time.Second {
+			t.Fatalf("Interval => %s; want 10s", got.Interval)
+		}
+	})
+
+	t.Run("defaults for tcp", func(t *testing.T) {
+		e := &Exposure{Type: &TCPExposure{}}
+
+		got := e.HealthCheck()
+		if got.Path != "" || got.Matcher != "" {
+			t.Fatalf("HealthCheck() => %#v; want no path/matcher for tcp", got)
+		}
+	})
+}
+
+func TestStatus_String(t *testing.T) {
+	if got, want := (&Status{Message: "deploying"}).String(), "deploying"; got != want {
+		t.Fatalf("String() => %q; want %q", got, want)
+	}
+
+	if got, want := (&Status{}).String(), ""; got != want {
+		t.Fatalf("String() => %q; want %q", got, want)
+	}
+
+	if got, want := (&Status{Error: errors.New("boom")}).String(), "boom"; got != want {
+		t.Fatalf("String() => %q; want %q", got, want)
+	}
+}
+
+func TestResourceStatus(t *testing.T) {
+	status := ResourceStatus("web", "CREATE_IN_PROGRESS", 3, 5)
+
+	if status.ID != "web" {
+		t.Fatalf("ID => %q; want %q", status.ID, "web")
+	}
+	if status.Message != "CREATE_IN_PROGRESS" {
+		t.Fatalf("Message => %q; want %q", status.Message, "CREATE_IN_PROGRESS")
+	}
+	if status.Progress == nil || status.Progress.Current != 3 || status.Progress.Total != 5 {
+		t.Fatalf("Progress => %#v; want Current=3 Total=5", status.Progress)
+	}
+}
+
+func TestJSONMessageStatusStream_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONMessageStream(&buf)
+
+	status := ResourceStatus("web", "CREATE_IN_PROGRESS", 3, 5)
+	if err := s.Publish(context.Background(), status); err != nil {
+		t.Fatal(err)
+	}
+
+	var msg jsonmessage.JSONMessage
+	if err := json.NewDecoder(&buf).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.ID != "web" {
+		t.Fatalf("ID => %q; want %q", msg.ID, "web")
+	}
+	if msg.Status != "CREATE_IN_PROGRESS" {
+		t.Fatalf("Status => %q; want %q", msg.Status, "CREATE_IN_PROGRESS")
+	}
+	if msg.Progress == nil || msg.Progress.Current != 3 || msg.Progress.Total != 5 {
+		t.Fatalf("Progress => %#v; want Current=3 Total=5", msg.Progress)
+	}
+	if msg.ProgressMessage != "3/5 events" {
+		t.Fatalf("ProgressMessage => %q; want %q", msg.ProgressMessage, "3/5 events")
+	}
+}
+
+func TestJSONMessageStatusStream_Publish_error(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONMessageStream(&buf)
+
+	if err := s.Publish(context.Background(), Status{Error: errors.New("boom")}); err != nil {
+		t.Fatal(err)
+	}
+
+	var msg jsonmessage.JSONMessage
+	if err := json.NewDecoder(&buf).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.ErrorMessage != "boom" {
+		t.Fatalf("ErrorMessage => %q; want %q", msg.ErrorMessage, "boom")
+	}
+	if msg.Error == nil || msg.Error.Message != "boom" {
+		t.Fatalf("Error => %#v; want Message=%q", msg.Error, "boom")
+	}
+}
+
+func TestConstraints_PlacementConstraintExpressions(t *testing.T) {
+	var nilConstraints *Constraints
+	if got := nilConstraints.PlacementConstraintExpressions(); got != nil {
+		t.Fatalf("PlacementConstraintExpressions() => %#v; want nil", got)
+	}
+
+	c := &Constraints{
+		AttributeExpressions: []string{"attribute:ecs.instance-type =~ m5.*"},
+		DistinctInstance:     true,
+	}
+	want := []string{"attribute:ecs.instance-type =~ m5.*", "distinctInstance"}
+	if got := c.PlacementConstraintExpressions(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("PlacementConstraintExpressions() => %#v; want %#v", got, want)
+	}
+}
+
+func TestProcess_Platform(t *testing.T) {
+	if got := (&Process{}).Platform(); got != "" {
+		t.Fatalf("Platform() => %q; want %q", got, "")
+	}
+
+	p := &Process{Constraints: &Constraints{Platform: "linux/arm64"}}
+	if got, want := p.Platform(), "linux/arm64"; got != want {
+		t.Fatalf("Platform() => %q; want %q", got, want)
+	}
+}
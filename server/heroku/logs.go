@@ -1,6 +1,7 @@
 package heroku
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/remind101/empire"
@@ -8,6 +9,35 @@ import (
 	"golang.org/x/net/context"
 )
 
+// defaultLogLines is the number of historical log lines returned when a
+// client requests logs without tail, and without an explicit lines value.
+const defaultLogLines = 100
+
+// PostLogsForm is the expected JSON request body for PostLogs, matching the
+// Heroku Platform API's log session contract.
+type PostLogsForm struct {
+	// Tail, when true, streams new log lines continuously until the
+	// client disconnects. When false (the default), only the most
+	// recent Lines log lines are written and the response is closed.
+	Tail bool `json:"tail"`
+
+	// Lines is the number of historical log lines to return when Tail is
+	// false. Defaults to defaultLogLines if unset.
+	Lines int `json:"lines"`
+
+	// Source restricts the log stream to a specific log source (e.g.
+	// "app" or "heroku").
+	Source string `json:"source"`
+
+	// Dyno restricts the log stream to a specific process type (e.g.
+	// "web").
+	Dyno string `json:"dyno"`
+
+	// Ps restricts the log stream to a specific process instance (e.g.
+	// "web.1").
+	Ps string `json:"ps"`
+}
+
 type PostLogs struct {
 	*empire.Empire
 }
@@ -18,9 +48,26 @@ func (h *PostLogs) ServeHTTPContext(ctx context.Context, w http.ResponseWriter,
 		return err
 	}
 
+	var form PostLogsForm
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+			return err
+		}
+	}
+
+	lines := form.Lines
+	if lines == 0 {
+		lines = defaultLogLines
+	}
+
+	filter := empire.LogFilter{
+		ProcessType: form.Dyno,
+		InstanceID:  form.Ps,
+		Source:      form.Source,
+	}
+
 	w.Header().Set("Content-Type", "application/json; boundary=NL")
 	rw := streamhttp.StreamingResponseWriter(w)
-	h.StreamLogs(a, rw)
 
-	return nil
+	return h.StreamLogs(ctx, a, rw, form.Tail, lines, filter)
 }